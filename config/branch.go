@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+
+	format "gopkg.in/src-d/go-git.v4/plumbing/format/config"
+)
+
+var (
+	ErrBranchConfigEmptyName = errors.New("branch config: empty name")
+	ErrBranchRemoteRequired  = errors.New("branch config: remote is required")
+	ErrBranchMergeRequired   = errors.New("branch config: merge is required")
+)
+
+const (
+	branchSection = "branch"
+	remoteKey     = "remote"
+	mergeKey      = "merge"
+	rebaseKey     = "rebase"
+)
+
+// BranchConfig contains the configuration for a given branch, as read from
+// the "branch.<name>" section of a git-config file.
+type BranchConfig struct {
+	// Name of the branch
+	Name string
+	// Remote name of the remote to fetch from and push to
+	Remote string
+	// Merge is the remote ref to merge into this branch, set by "git pull"
+	Merge string
+	// Rebase instructs "git pull" to rebase this branch on top of the
+	// fetched branch instead of merging, valid values are "true", "false"
+	// and "interactive"
+	Rebase string
+
+	raw *format.Subsection
+}
+
+// Validate validates the fields and returns an error if required ones are
+// missing or invalid
+func (b *BranchConfig) Validate() error {
+	if b.Name == "" {
+		return ErrBranchConfigEmptyName
+	}
+
+	if b.Remote != "" && b.Merge == "" {
+		return ErrBranchMergeRequired
+	}
+
+	if b.Merge != "" && b.Remote == "" {
+		return ErrBranchRemoteRequired
+	}
+
+	return nil
+}
+
+func (c *Config) unmarshalBranches() {
+	s := c.merged.Section(branchSection)
+	for _, sub := range s.Subsections {
+		b := &BranchConfig{}
+		b.unmarshal(sub)
+
+		c.Branches[b.Name] = b
+	}
+}
+
+func (c *Config) marshalBranches() {
+	live := make(map[string]bool, len(c.Branches))
+	for name, b := range c.Branches {
+		live[name] = true
+
+		s := c.target(branchSection, name).Section(branchSection)
+		s.Subsections = replaceOrAppendSubsection(s.Subsections, b.marshal())
+	}
+
+	c.pruneSubsections(branchSection, live)
+}
+
+func (b *BranchConfig) unmarshal(s *format.Subsection) {
+	b.raw = s
+
+	b.Name = s.Name
+	b.Remote = s.Options.Get(remoteKey)
+	b.Merge = s.Options.Get(mergeKey)
+	b.Rebase = s.Options.Get(rebaseKey)
+}
+
+func (b *BranchConfig) marshal() *format.Subsection {
+	if b.raw == nil {
+		b.raw = &format.Subsection{}
+	}
+
+	b.raw.Name = b.Name
+	b.raw.Options = replaceOption(b.raw.Options, remoteKey, valueOrNone(b.Remote))
+	b.raw.Options = replaceOption(b.raw.Options, mergeKey, valueOrNone(b.Merge))
+	b.raw.Options = replaceOption(b.raw.Options, rebaseKey, valueOrNone(b.Rebase))
+
+	return b.raw
+}
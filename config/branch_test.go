@@ -0,0 +1,52 @@
+package config
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type BranchSuite struct{}
+
+var _ = Suite(&BranchSuite{})
+
+func (s *BranchSuite) TestUnmarshalMarshalRoundTrip(c *C) {
+	raw := []byte("[core]\n\tbare = false\n[branch \"master\"]\n\tremote = origin\n\tmerge = refs/heads/master\n\trebase = true\n")
+
+	cfg := NewConfig()
+	c.Assert(cfg.Unmarshal(raw), IsNil)
+
+	b, ok := cfg.Branches["master"]
+	c.Assert(ok, Equals, true)
+	c.Assert(b.Remote, Equals, "origin")
+	c.Assert(b.Merge, Equals, "refs/heads/master")
+	c.Assert(b.Rebase, Equals, "true")
+
+	out, err := cfg.Marshal()
+	c.Assert(err, IsNil)
+	c.Assert(string(out), Equals, string(raw))
+}
+
+func (s *BranchSuite) TestMarshalClearsRebase(c *C) {
+	raw := []byte("[core]\n\tbare = false\n[branch \"master\"]\n\tremote = origin\n\tmerge = refs/heads/master\n\trebase = true\n")
+
+	cfg := NewConfig()
+	c.Assert(cfg.Unmarshal(raw), IsNil)
+	cfg.Branches["master"].Rebase = ""
+
+	out, err := cfg.Marshal()
+	c.Assert(err, IsNil)
+	c.Assert(string(out), Equals, "[core]\n\tbare = false\n[branch \"master\"]\n\tremote = origin\n\tmerge = refs/heads/master\n")
+}
+
+func (s *BranchSuite) TestValidate(c *C) {
+	b := &BranchConfig{Name: "master"}
+	c.Assert(b.Validate(), IsNil)
+
+	b = &BranchConfig{}
+	c.Assert(b.Validate(), Equals, ErrBranchConfigEmptyName)
+
+	b = &BranchConfig{Name: "master", Remote: "origin"}
+	c.Assert(b.Validate(), Equals, ErrBranchMergeRequired)
+
+	b = &BranchConfig{Name: "master", Merge: "refs/heads/master"}
+	c.Assert(b.Validate(), Equals, ErrBranchRemoteRequired)
+}
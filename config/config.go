@@ -38,21 +38,61 @@ type Config struct {
 		// working directory associated with it
 		IsBare bool
 	}
+	// User holds the identity (user.name and user.email) used for commits
+	User UserConfig
 	// Remote list of repository remotes
 	Remotes map[string]*RemoteConfig
+	// Branches list of branch config, by branch name
+	Branches map[string]*BranchConfig
+	// Submodules list of submodule config, by submodule name
+	Submodules map[string]*SubmoduleConfig
+	// URLs list of url rewrite rules, by the base url they rewrite to
+	URLs map[string]*URLConfig
+	// Includes is the list of [include] and [includeIf] directives found
+	// while unmarshalling: every [include] first, then every [includeIf],
+	// each group in the order it appears in the file. An [include] declared
+	// after an [includeIf] is still listed, and merged, before it
+	Includes []*Include
 
 	// contains the raw information of a config file, the main goal is preserve
 	// the parsed information from the original format, to avoid missing
 	// unsupported features.
 	raw *format.Config
+
+	// merged is raw plus everything pulled in through Includes, it is what
+	// the unmarshal* helpers read from to populate the typed fields above
+	merged *format.Config
+
+	// included holds the raw contents of every file loaded through Includes,
+	// keyed by its resolved absolute path, so that options coming from an
+	// include can be written back to the file they came from instead of to
+	// raw
+	included map[string]*format.Config
+
+	// origin maps a "section\x00subsection" pair to the absolute path of the
+	// include file that contributed it, it is empty for anything that
+	// belongs to raw itself
+	origin map[string]string
+
+	// path is the absolute path this Config was loaded from, empty when it
+	// was built in memory or via Unmarshal
+	path string
 }
 
 // NewConfig returns a new empty Config
 func NewConfig() *Config {
-	return &Config{
-		Remotes: make(map[string]*RemoteConfig, 0),
-		raw:     format.New(),
+	c := &Config{
+		Remotes:    make(map[string]*RemoteConfig, 0),
+		Branches:   make(map[string]*BranchConfig, 0),
+		Submodules: make(map[string]*SubmoduleConfig, 0),
+		URLs:       make(map[string]*URLConfig, 0),
+		raw:        format.New(),
+		included:   make(map[string]*format.Config, 0),
+		origin:     make(map[string]string, 0),
 	}
+	c.merged = c.raw
+
+	return c
 }
 
 // Validate validate the fields and set the default values
@@ -67,18 +107,63 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	for name, b := range c.Branches {
+		if b.Name != name {
+			return ErrInvalid
+		}
+
+		if err := b.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for name, s := range c.Submodules {
+		if s.Name != name {
+			return ErrInvalid
+		}
+
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for name, u := range c.URLs {
+		if u.Name != name {
+			return ErrInvalid
+		}
+
+		if err := u.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 const (
 	remoteSection = "remote"
 	coreSection   = "core"
+	userSection   = "user"
 	fetchKey      = "fetch"
 	urlKey        = "url"
 	bareKey       = "bare"
+	nameKey       = "name"
+	emailKey      = "email"
 )
 
-// Unmarshal parses a git-config file and stores it
+// UserConfig is the identity used to sign commits and tags, as read from
+// the "user" section of a git-config file.
+type UserConfig struct {
+	// Name is the value of user.name
+	Name string
+	// Email is the value of user.email
+	Email string
+}
+
+// Unmarshal parses a git-config file and stores it. Included files, if any,
+// are not resolved here since doing so requires a base directory to resolve
+// relative paths against; use ReadConfig to also honor [include] and
+// [includeIf] directives.
 func (c *Config) Unmarshal(b []byte) error {
 	r := bytes.NewBuffer(b)
 	d := format.NewDecoder(r)
@@ -88,20 +173,32 @@ func (c *Config) Unmarshal(b []byte) error {
 		return err
 	}
 
+	c.merged = c.raw
+	c.unmarshalIncludes()
 	c.unmarshalCore()
+	c.unmarshalUser()
 	c.unmarshalRemotes()
+	c.unmarshalBranches()
+	c.unmarshalSubmodules()
+	c.unmarshalURLs()
 	return nil
 }
 
 func (c *Config) unmarshalCore() {
-	s := c.raw.Section(coreSection)
+	s := c.merged.Section(coreSection)
 	if s.Options.Get(bareKey) == "true" {
 		c.Core.IsBare = true
 	}
 }
 
+func (c *Config) unmarshalUser() {
+	s := c.merged.Section(userSection)
+	c.User.Name = s.Options.Get(nameKey)
+	c.User.Email = s.Options.Get(emailKey)
+}
+
 func (c *Config) unmarshalRemotes() {
-	s := c.raw.Section(remoteSection)
+	s := c.merged.Section(remoteSection)
 	for _, sub := range s.Subsections {
 		r := &RemoteConfig{}
 		r.unmarshal(sub)
@@ -110,10 +207,17 @@ func (c *Config) unmarshalRemotes() {
 	}
 }
 
-// Marshal returns Config encoded as a git-config file
+// Marshal returns Config encoded as a git-config file. Options that were
+// pulled in from an included file are written back into that file's raw
+// representation rather than into the top-level one; use MarshalIncludes to
+// retrieve their encoded contents.
 func (c *Config) Marshal() ([]byte, error) {
 	c.marshalCore()
+	c.marshalUser()
 	c.marshalRemotes()
+	c.marshalBranches()
+	c.marshalSubmodules()
+	c.marshalURLs()
 
 	buf := bytes.NewBuffer(nil)
 	if err := format.NewEncoder(buf).Encode(c.raw); err != nil {
@@ -123,19 +227,127 @@ func (c *Config) Marshal() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// MarshalIncludes returns the encoded contents of every included file that
+// was loaded into this Config, keyed by its resolved
+// absolute path. Callers that persist includes (for example a Storer backed
+// by the filesystem) should call Marshal first and then write each of these
+// back to its corresponding path.
+func (c *Config) MarshalIncludes() (map[string][]byte, error) {
+	result := make(map[string][]byte, len(c.included))
+	for path, raw := range c.included {
+		buf := bytes.NewBuffer(nil)
+		if err := format.NewEncoder(buf).Encode(raw); err != nil {
+			return nil, err
+		}
+
+		result[path] = buf.Bytes()
+	}
+
+	return result, nil
+}
+
+// target returns the raw config that options of the given section and
+// subsection should be written back to: the include they came from, or raw
+// itself when they belong to the top-level file.
+func (c *Config) target(section, subsection string) *format.Config {
+	if path, ok := c.origin[originKey(section, subsection)]; ok {
+		if raw, ok := c.included[path]; ok {
+			return raw
+		}
+	}
+
+	return c.raw
+}
+
 func (c *Config) marshalCore() {
-	s := c.raw.Section(coreSection)
+	s := c.target(coreSection, "").Section(coreSection)
 	s.SetOption(bareKey, fmt.Sprintf("%t", c.Core.IsBare))
 }
 
+func (c *Config) marshalUser() {
+	target := c.target(userSection, "")
+	if c.User.Name == "" && c.User.Email == "" && !hasSection(target, userSection) {
+		return
+	}
+
+	s := target.Section(userSection)
+	s.Options = replaceOption(s.Options, nameKey, valueOrNone(c.User.Name))
+	s.Options = replaceOption(s.Options, emailKey, valueOrNone(c.User.Email))
+}
+
+// hasSection reports whether raw already has a section called name, without
+// creating one as Section() would.
+func hasSection(raw *format.Config, name string) bool {
+	for _, s := range raw.Sections {
+		if s.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// valueOrNone returns a single-element slice holding v, or nil when v is
+// empty, for use with replaceOption.
+func valueOrNone(v string) []string {
+	if v == "" {
+		return nil
+	}
+
+	return []string{v}
+}
+
 func (c *Config) marshalRemotes() {
-	s := c.raw.Section(remoteSection)
-	s.Subsections = make(format.Subsections, len(c.Remotes))
+	live := make(map[string]bool, len(c.Remotes))
+	for name, r := range c.Remotes {
+		live[name] = true
+
+		s := c.target(remoteSection, name).Section(remoteSection)
+		s.Subsections = replaceOrAppendSubsection(s.Subsections, r.marshal())
+	}
+
+	c.pruneSubsections(remoteSection, live)
+}
+
+// replaceOrAppendSubsection puts sub into subs: in the same slot it already
+// occupies, if subs has an entry with the same name, or at the end
+// otherwise. Unlike a remove-then-append, this never reorders subsections
+// that were already there, so repeated Marshal calls produce the same
+// output regardless of the random order map iteration visits entries in.
+func replaceOrAppendSubsection(subs format.Subsections, sub *format.Subsection) format.Subsections {
+	for i, s := range subs {
+		if s.Name == sub.Name {
+			subs[i] = sub
+			return subs
+		}
+	}
+
+	return append(subs, sub)
+}
+
+// pruneSubsections drops, from every raw config this Config knows about, any
+// subsection of section whose name is not in live, so that deleting an entry
+// from the corresponding map (e.g. c.Remotes) removes it from the marshaled
+// output instead of leaving a stale copy behind.
+func (c *Config) pruneSubsections(section string, live map[string]bool) {
+	prune := func(raw *format.Config) {
+		if !hasSection(raw, section) {
+			return
+		}
+
+		s := raw.Section(section)
+		kept := make(format.Subsections, 0, len(s.Subsections))
+		for _, sub := range s.Subsections {
+			if live[sub.Name] {
+				kept = append(kept, sub)
+			}
+		}
+		s.Subsections = kept
+	}
 
-	var i int
-	for _, r := range c.Remotes {
-		s.Subsections[i] = r.marshal()
-		i++
+	prune(c.raw)
+	for _, raw := range c.included {
+		prune(raw)
 	}
 }
 
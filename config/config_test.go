@@ -0,0 +1,78 @@
+package config
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type ConfigSuite struct{}
+
+var _ = Suite(&ConfigSuite{})
+
+func (s *ConfigSuite) TestUnmarshalMarshalRoundTrip(c *C) {
+	raw := []byte("[core]\n\tbare = true\n[remote \"origin\"]\n\turl = git@example.com:foo/bar.git\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n")
+
+	cfg := NewConfig()
+	c.Assert(cfg.Unmarshal(raw), IsNil)
+	c.Assert(cfg.Core.IsBare, Equals, true)
+	c.Assert(cfg.Remotes, HasLen, 1)
+	c.Assert(cfg.Remotes["origin"].URL, Equals, "git@example.com:foo/bar.git")
+
+	out, err := cfg.Marshal()
+	c.Assert(err, IsNil)
+	c.Assert(string(out), Equals, string(raw))
+}
+
+// TestMarshalStableOrder makes sure repeated Marshal calls on an unmodified
+// Config always produce byte-identical output, regardless of the random
+// order Go visits map entries in.
+func (s *ConfigSuite) TestMarshalStableOrder(c *C) {
+	raw := []byte("[core]\n\tbare = false\n[remote \"a\"]\n\turl = a.git\n\tfetch = +refs/heads/*:refs/remotes/a/*\n[remote \"b\"]\n\turl = b.git\n\tfetch = +refs/heads/*:refs/remotes/b/*\n[remote \"c\"]\n\turl = c.git\n\tfetch = +refs/heads/*:refs/remotes/c/*\n")
+
+	cfg := NewConfig()
+	c.Assert(cfg.Unmarshal(raw), IsNil)
+
+	first, err := cfg.Marshal()
+	c.Assert(err, IsNil)
+
+	for i := 0; i < 10; i++ {
+		out, err := cfg.Marshal()
+		c.Assert(err, IsNil)
+		c.Assert(string(out), Equals, string(first))
+	}
+}
+
+// TestMarshalPrunesDeletedRemote makes sure removing an entry from
+// c.Remotes actually drops its subsection from the marshaled output,
+// instead of leaving a stale copy behind.
+func (s *ConfigSuite) TestMarshalPrunesDeletedRemote(c *C) {
+	raw := []byte("[core]\n\tbare = false\n[remote \"origin\"]\n\turl = origin.git\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n[remote \"fork\"]\n\turl = fork.git\n\tfetch = +refs/heads/*:refs/remotes/fork/*\n")
+
+	cfg := NewConfig()
+	c.Assert(cfg.Unmarshal(raw), IsNil)
+
+	delete(cfg.Remotes, "origin")
+
+	out, err := cfg.Marshal()
+	c.Assert(err, IsNil)
+	c.Assert(string(out), Equals, "[core]\n\tbare = false\n[remote \"fork\"]\n\turl = fork.git\n\tfetch = +refs/heads/*:refs/remotes/fork/*\n")
+}
+
+func (s *ConfigSuite) TestGenericGetSet(c *C) {
+	cfg := NewConfig()
+	c.Assert(cfg.Unmarshal(nil), IsNil)
+
+	_, ok := cfg.Get("http", "https://example.com", "sslVerify")
+	c.Assert(ok, Equals, false)
+
+	cfg.SetString("http", "https://example.com", "sslVerify", "false")
+	v, ok := cfg.Get("http", "https://example.com", "sslVerify")
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, "false")
+
+	cfg.AddAll("http", "https://example.com", "extraHeader", "A: 1")
+	cfg.AddAll("http", "https://example.com", "extraHeader", "B: 2")
+	c.Assert(cfg.GetAll("http", "https://example.com", "extraHeader"), DeepEquals, []string{"A: 1", "B: 2"})
+
+	cfg.Unset("http", "https://example.com", "extraHeader")
+	c.Assert(cfg.GetAll("http", "https://example.com", "extraHeader"), HasLen, 0)
+}
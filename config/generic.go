@@ -0,0 +1,138 @@
+package config
+
+import (
+	"strconv"
+
+	format "gopkg.in/src-d/go-git.v4/plumbing/format/config"
+)
+
+// Get returns the value of section.[subsection.]key, and whether it was
+// set at all. For a multi-valued option it returns the last value, mirroring
+// "git config --get". Reads honor any merged-in includes.
+func (c *Config) Get(section, subsection, key string) (string, bool) {
+	all := c.GetAll(section, subsection, key)
+	if len(all) == 0 {
+		return "", false
+	}
+
+	return all[len(all)-1], true
+}
+
+// GetAll returns every value set for section.[subsection.]key, in the order
+// they were defined, mirroring "git config --get-all". Reads honor any
+// merged-in includes.
+func (c *Config) GetAll(section, subsection, key string) []string {
+	return c.options(section, subsection).GetAll(key)
+}
+
+// SetString sets section.[subsection.]key to value, replacing any value(s)
+// it previously had, mirroring "git config <key> <value>". For a section
+// with a typed equivalent (Core, User, Remotes, Branches, Submodules, URLs)
+// set the typed field instead: Marshal always re-derives that section from
+// it, which would otherwise undo this call.
+func (c *Config) SetString(section, subsection, key, value string) {
+	c.setAll(section, subsection, key, []string{value})
+}
+
+// SetBool sets section.[subsection.]key to value, replacing any value(s) it
+// previously had.
+func (c *Config) SetBool(section, subsection, key string, value bool) {
+	c.SetString(section, subsection, key, strconv.FormatBool(value))
+}
+
+// SetInt sets section.[subsection.]key to value, replacing any value(s) it
+// previously had.
+func (c *Config) SetInt(section, subsection, key string, value int) {
+	c.SetString(section, subsection, key, strconv.Itoa(value))
+}
+
+// AddAll appends value to section.[subsection.]key without removing any
+// value(s) it already had, mirroring "git config --add".
+func (c *Config) AddAll(section, subsection, key, value string) {
+	s, sub := c.resolveTarget(section, subsection)
+	if sub != nil {
+		sub.Options = append(sub.Options, &format.Option{Key: key, Value: value})
+		return
+	}
+
+	s.Options = append(s.Options, &format.Option{Key: key, Value: value})
+}
+
+// Unset removes every value of section.[subsection.]key, mirroring
+// "git config --unset-all". See the note on SetString regarding sections
+// that have a typed equivalent.
+func (c *Config) Unset(section, subsection, key string) {
+	c.setAll(section, subsection, key, nil)
+}
+
+// options returns the options of section.subsection (or of section itself,
+// when subsection is empty) in the merged, include-aware view of this
+// Config.
+func (c *Config) options(section, subsection string) format.Options {
+	s := c.merged.Section(section)
+	if subsection == "" {
+		return s.Options
+	}
+
+	for _, sub := range s.Subsections {
+		if sub.Name == subsection {
+			return sub.Options
+		}
+	}
+
+	return nil
+}
+
+// resolveTarget returns the Section and, when subsection is not empty, the
+// Subsection that writes to section.[subsection.] should go to: whichever
+// raw config last contributed that section/subsection, or raw itself for
+// one never seen before.
+func (c *Config) resolveTarget(section, subsection string) (*format.Section, *format.Subsection) {
+	s := c.target(section, subsection).Section(section)
+	if subsection == "" {
+		return s, nil
+	}
+
+	return s, subsectionOf(s, subsection)
+}
+
+func (c *Config) setAll(section, subsection, key string, values []string) {
+	s, sub := c.resolveTarget(section, subsection)
+	if sub != nil {
+		sub.Options = replaceOption(sub.Options, key, values)
+		return
+	}
+
+	s.Options = replaceOption(s.Options, key, values)
+}
+
+// replaceOption returns opts with every occurrence of key removed and one
+// new option appended per entry in values.
+func replaceOption(opts format.Options, key string, values []string) format.Options {
+	result := make(format.Options, 0, len(opts)+len(values))
+	for _, o := range opts {
+		if o.Key != key {
+			result = append(result, o)
+		}
+	}
+
+	for _, v := range values {
+		result = append(result, &format.Option{Key: key, Value: v})
+	}
+
+	return result
+}
+
+// subsectionOf finds, within s, the subsection called name, creating an
+// empty one if it isn't there yet.
+func subsectionOf(s *format.Section, name string) *format.Subsection {
+	for _, sub := range s.Subsections {
+		if sub.Name == name {
+			return sub
+		}
+	}
+
+	sub := &format.Subsection{Name: name}
+	s.Subsections = append(s.Subsections, sub)
+	return sub
+}
@@ -0,0 +1,330 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	format "gopkg.in/src-d/go-git.v4/plumbing/format/config"
+)
+
+const (
+	includeSection   = "include"
+	includeIfSection = "includeIf"
+	pathKey          = "path"
+
+	condGitDir  = "gitdir"
+	condGitDirI = "gitdir/i"
+	condBranch  = "onbranch"
+)
+
+// ErrIncludeCycle is returned by ReadConfig when an [include] or
+// [includeIf] directive, directly or transitively, refers back to a file
+// that is already being loaded.
+var ErrIncludeCycle = errors.New("config: include cycle detected")
+
+// Include represents a single [include] or [includeIf] directive found
+// while unmarshalling a config file.
+type Include struct {
+	// Path is the value of the "path" option, exactly as it appears in the
+	// file, before "~" expansion or resolution against a base directory.
+	Path string
+	// Condition is empty for a plain [include]. For an
+	// [includeIf "<condition>:<pattern>"] it is "gitdir", "gitdir/i" or
+	// "onbranch", or any other condition exactly as written, which never
+	// matches.
+	Condition string
+	// Pattern is the match expression that follows Condition.
+	Pattern string
+
+	// resolvedPath is the absolute path Path was resolved to the last time
+	// this Include was loaded by ReadConfig, empty otherwise.
+	resolvedPath string
+}
+
+// matches reports whether the include should be applied when loading the
+// config for the repository whose git directory is gitDir, given the
+// currently checked out branch. gitDir is the same for every file in the
+// include chain: "gitdir:" always refers to the repository actually being
+// operated on, not to wherever the includeIf happens to be declared.
+func (i *Include) matches(gitDir, branch string) bool {
+	switch i.Condition {
+	case "":
+		return true
+	case condGitDir:
+		return matchGlob(expandTilde(i.Pattern), gitDir)
+	case condGitDirI:
+		return matchGlob(strings.ToLower(expandTilde(i.Pattern)), strings.ToLower(gitDir))
+	case condBranch:
+		return matchGlob(i.Pattern, branch)
+	default:
+		return false
+	}
+}
+
+func (c *Config) unmarshalIncludes() {
+	c.Includes = nil
+
+	for _, path := range c.raw.Section(includeSection).Options.GetAll(pathKey) {
+		c.Includes = append(c.Includes, &Include{Path: path})
+	}
+
+	for _, sub := range c.raw.Section(includeIfSection).Subsections {
+		cond, pattern := splitCondition(sub.Name)
+		for _, path := range sub.Options.GetAll(pathKey) {
+			c.Includes = append(c.Includes, &Include{
+				Path:      path,
+				Condition: cond,
+				Pattern:   pattern,
+			})
+		}
+	}
+}
+
+// splitCondition splits the subsection name of an [includeIf "..."] block,
+// e.g. "gitdir:~/work/**", into its condition and pattern. An unrecognized
+// condition is returned as written, never as empty, so that it is not
+// mistaken for a plain [include].
+func splitCondition(raw string) (cond, pattern string) {
+	for _, c := range []string{condGitDirI, condGitDir, condBranch} {
+		if strings.HasPrefix(raw, c+":") {
+			return c, strings.TrimPrefix(raw, c+":")
+		}
+	}
+
+	if i := strings.Index(raw, ":"); i >= 0 {
+		return raw[:i], raw[i+1:]
+	}
+
+	return raw, ""
+}
+
+// ReadConfig reads the git-config file at path, merging in every [include]
+// and [includeIf] it references, directly or transitively. gitDir is the
+// git directory of the repository this config belongs to, used to evaluate
+// "gitdir:" and "gitdir/i:" conditions: it is the same for every file in the
+// include chain, regardless of which one declares the directive. branch is
+// the name of the currently checked out branch, used to evaluate
+// "onbranch:" conditions; pass the empty string if it is not known.
+func ReadConfig(path, gitDir, branch string) (*Config, error) {
+	c := NewConfig()
+	if err := c.readFile(path, gitDir, branch, map[string]bool{}); err != nil {
+		return nil, err
+	}
+
+	c.buildMerged()
+	c.unmarshalCore()
+	c.unmarshalUser()
+	c.unmarshalRemotes()
+	c.unmarshalBranches()
+	c.unmarshalSubmodules()
+	c.unmarshalURLs()
+	return c, nil
+}
+
+// readFile decodes the file at path into c and recursively loads whatever
+// it includes, recording each included file's own raw contents in
+// c.included so Marshal can later tell them apart from c.raw. gitDir is
+// passed down unchanged to every nested include, see ReadConfig. seen holds
+// the chain of files currently being loaded, from the root down to path; it
+// is used to detect cycles and must not contain a file more than once at a
+// time, so that two sibling includes referencing the same file (a diamond,
+// not a cycle) don't falsely trip ErrIncludeCycle.
+func (c *Config) readFile(path, gitDir, branch string, seen map[string]bool) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if seen[abs] {
+		return ErrIncludeCycle
+	}
+	seen[abs] = true
+	defer delete(seen, abs)
+
+	b, err := ioutil.ReadFile(abs)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Unmarshal(b); err != nil {
+		return err
+	}
+	c.path = abs
+
+	// expanded rebuilds c.Includes with each include's own includes spliced
+	// in right after it, mirroring how git expands the file in place, so
+	// buildMerged later applies them in true file-expansion order instead of
+	// always moving nested includes to the end.
+	dir := filepath.Dir(abs)
+	expanded := make([]*Include, 0, len(c.Includes))
+	for _, inc := range c.Includes {
+		expanded = append(expanded, inc)
+
+		if !inc.matches(gitDir, branch) {
+			continue
+		}
+
+		target, err := expandPath(inc.Path, dir)
+		if err != nil {
+			return err
+		}
+		inc.resolvedPath = target
+
+		included := NewConfig()
+		if err := included.readFile(target, gitDir, branch, seen); err != nil {
+			return err
+		}
+
+		c.included[target] = included.raw
+		for path, raw := range included.included {
+			c.included[path] = raw
+		}
+		for _, nested := range included.Includes {
+			if nested.resolvedPath != "" {
+				expanded = append(expanded, nested)
+			}
+		}
+	}
+	c.Includes = expanded
+
+	return nil
+}
+
+// expandPath resolves p, which may start with "~" or be relative to dir,
+// into an absolute path.
+func expandPath(p, dir string) (string, error) {
+	p = expandTilde(p)
+	if filepath.IsAbs(p) {
+		return p, nil
+	}
+
+	return filepath.Abs(filepath.Join(dir, p))
+}
+
+func expandTilde(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return p
+	}
+
+	if p == "~" {
+		return u.HomeDir
+	}
+
+	return filepath.Join(u.HomeDir, p[2:])
+}
+
+// matchGlob reports whether name matches pattern, additionally supporting a
+// trailing "/**" to mean "this directory or anything below it", as used by
+// gitdir: and gitdir/i: conditions.
+func matchGlob(pattern, name string) bool {
+	if pattern == "" {
+		return false
+	}
+
+	pattern = strings.TrimSuffix(pattern, "/")
+	name = strings.TrimSuffix(name, "/")
+
+	if strings.HasSuffix(pattern, "/**") {
+		prefix := strings.TrimSuffix(pattern, "/**")
+		return name == prefix || strings.HasPrefix(name, prefix+"/")
+	}
+
+	ok, err := filepath.Match(pattern, name)
+	return err == nil && ok
+}
+
+// originKey builds the key used by c.origin to remember which file a
+// section, or one of its subsections, was last set in.
+func originKey(section, subsection string) string {
+	return section + "\x00" + subsection
+}
+
+// buildMerged recomputes c.merged from c.raw and every file in c.included,
+// applied in the order they were included, and records in c.origin which
+// file each resulting section or subsection came from.
+func (c *Config) buildMerged() {
+	raws := []*format.Config{c.raw}
+	for _, inc := range c.Includes {
+		if inc.resolvedPath == "" {
+			continue
+		}
+
+		raw, ok := c.included[inc.resolvedPath]
+		if !ok {
+			continue
+		}
+
+		raws = append(raws, raw)
+		for _, s := range raw.Sections {
+			c.origin[originKey(s.Name, "")] = inc.resolvedPath
+			for _, sub := range s.Subsections {
+				c.origin[originKey(s.Name, sub.Name)] = inc.resolvedPath
+			}
+		}
+	}
+
+	merged := format.New()
+	for _, name := range sectionNames(raws) {
+		merged.Sections = append(merged.Sections, mergeSection(name, raws))
+	}
+
+	c.merged = merged
+}
+
+// sectionNames returns the name of every section present in raws, in the
+// order it is first seen.
+func sectionNames(raws []*format.Config) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, raw := range raws {
+		for _, s := range raw.Sections {
+			if !seen[s.Name] {
+				seen[s.Name] = true
+				names = append(names, s.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// mergeSection builds a synthetic section called name out of the section of
+// that name in each of raws, appended in order, so that options set by a
+// later include take precedence over earlier ones when read back with Get.
+// Subsections sharing a name (e.g. the same remote defined partly in an
+// include) are merged together rather than kept as separate entries.
+func mergeSection(name string, raws []*format.Config) *format.Section {
+	merged := &format.Section{Name: name}
+
+	var subNames []string
+	bySubName := make(map[string]*format.Subsection)
+	for _, raw := range raws {
+		s := raw.Section(name)
+		merged.Options = append(merged.Options, s.Options...)
+
+		for _, sub := range s.Subsections {
+			existing, ok := bySubName[sub.Name]
+			if !ok {
+				existing = &format.Subsection{Name: sub.Name}
+				bySubName[sub.Name] = existing
+				subNames = append(subNames, sub.Name)
+			}
+
+			existing.Options = append(existing.Options, sub.Options...)
+		}
+	}
+
+	for _, subName := range subNames {
+		merged.Subsections = append(merged.Subsections, bySubName[subName])
+	}
+
+	return merged
+}
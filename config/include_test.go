@@ -0,0 +1,143 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type IncludeSuite struct {
+	dir string
+}
+
+var _ = Suite(&IncludeSuite{})
+
+func (s *IncludeSuite) SetUpTest(c *C) {
+	dir, err := ioutil.TempDir("", "go-git-config-include")
+	c.Assert(err, IsNil)
+	s.dir = dir
+}
+
+func (s *IncludeSuite) TearDownTest(c *C) {
+	os.RemoveAll(s.dir)
+}
+
+func (s *IncludeSuite) write(c *C, name, content string) string {
+	path := filepath.Join(s.dir, name)
+	c.Assert(ioutil.WriteFile(path, []byte(content), 0644), IsNil)
+	return path
+}
+
+func (s *IncludeSuite) TestPlainInclude(c *C) {
+	s.write(c, "shared.gitconfig", "[user]\n\temail = shared@example.com\n")
+	main := s.write(c, "main.gitconfig", "[include]\n\tpath = shared.gitconfig\n")
+
+	cfg, err := ReadConfig(main, s.dir, "")
+	c.Assert(err, IsNil)
+	c.Assert(cfg.User.Email, Equals, "shared@example.com")
+}
+
+func (s *IncludeSuite) TestIncludeIfGitDirMatch(c *C) {
+	s.write(c, "shared.gitconfig", "[user]\n\temail = match@example.com\n")
+	main := s.write(c, "main.gitconfig",
+		"[includeIf \"gitdir:"+s.dir+"\"]\n\tpath = shared.gitconfig\n")
+
+	cfg, err := ReadConfig(main, s.dir, "")
+	c.Assert(err, IsNil)
+	c.Assert(cfg.User.Email, Equals, "match@example.com")
+}
+
+func (s *IncludeSuite) TestIncludeIfGitDirNoMatch(c *C) {
+	s.write(c, "shared.gitconfig", "[user]\n\temail = nomatch@example.com\n")
+	main := s.write(c, "main.gitconfig",
+		"[includeIf \"gitdir:/somewhere/else\"]\n\tpath = shared.gitconfig\n")
+
+	cfg, err := ReadConfig(main, s.dir, "")
+	c.Assert(err, IsNil)
+	c.Assert(cfg.User.Email, Equals, "")
+}
+
+// TestIncludeIfGitDirUsesRepoGitDir makes sure "gitdir:" is matched against
+// the repository's own git directory, not the directory of the file that
+// declares the includeIf, which may be anywhere (e.g. a global ~/.gitconfig
+// included from a completely unrelated location).
+func (s *IncludeSuite) TestIncludeIfGitDirUsesRepoGitDir(c *C) {
+	repoDir := filepath.Join(s.dir, "repo", ".git")
+	c.Assert(os.MkdirAll(repoDir, 0755), IsNil)
+
+	s.write(c, "shared.gitconfig", "[user]\n\temail = repo@example.com\n")
+	main := s.write(c, "main.gitconfig",
+		"[includeIf \"gitdir:"+repoDir+"\"]\n\tpath = shared.gitconfig\n")
+
+	cfg, err := ReadConfig(main, repoDir, "")
+	c.Assert(err, IsNil)
+	c.Assert(cfg.User.Email, Equals, "repo@example.com")
+
+	cfg, err = ReadConfig(main, s.dir, "")
+	c.Assert(err, IsNil)
+	c.Assert(cfg.User.Email, Equals, "")
+}
+
+func (s *IncludeSuite) TestIncludeIfOnBranch(c *C) {
+	s.write(c, "shared.gitconfig", "[user]\n\temail = branch@example.com\n")
+	main := s.write(c, "main.gitconfig",
+		"[includeIf \"onbranch:main\"]\n\tpath = shared.gitconfig\n")
+
+	cfg, err := ReadConfig(main, s.dir, "main")
+	c.Assert(err, IsNil)
+	c.Assert(cfg.User.Email, Equals, "branch@example.com")
+
+	cfg, err = ReadConfig(main, s.dir, "other")
+	c.Assert(err, IsNil)
+	c.Assert(cfg.User.Email, Equals, "")
+}
+
+func (s *IncludeSuite) TestIncludeCycle(c *C) {
+	aPath := filepath.Join(s.dir, "a.gitconfig")
+	bPath := filepath.Join(s.dir, "b.gitconfig")
+	s.write(c, "a.gitconfig", "[include]\n\tpath = b.gitconfig\n")
+	s.write(c, "b.gitconfig", "[include]\n\tpath = a.gitconfig\n")
+
+	_, err := ReadConfig(aPath, s.dir, "")
+	c.Assert(err, Equals, ErrIncludeCycle)
+
+	// bPath is unused above other than to document that either side of the
+	// cycle fails the same way.
+	_ = bPath
+}
+
+// TestDiamondIncludeIsNotACycle makes sure two sibling includes that both
+// point at the same, otherwise non-cyclic, file succeed: this is a diamond,
+// not a cycle, and must not be rejected with ErrIncludeCycle.
+func (s *IncludeSuite) TestDiamondIncludeIsNotACycle(c *C) {
+	s.write(c, "shared.gitconfig", "[user]\n\temail = diamond@example.com\n")
+	main := s.write(c, "main.gitconfig", `[includeIf "gitdir:`+s.dir+`"]
+	path = shared.gitconfig
+[includeIf "gitdir:`+s.dir+`"]
+	path = shared.gitconfig
+`)
+
+	cfg, err := ReadConfig(main, s.dir, "")
+	c.Assert(err, IsNil)
+	c.Assert(cfg.User.Email, Equals, "diamond@example.com")
+}
+
+// TestNestedIncludeExpandsInPlace makes sure an include's own includes are
+// merged in right where that include was declared, not shifted to the end
+// of the chain: root includes A then B, and A itself includes C, so the
+// true expansion order is [root, A, C, B] and B, being last, should win.
+func (s *IncludeSuite) TestNestedIncludeExpandsInPlace(c *C) {
+	s.write(c, "c.gitconfig", "[user]\n\temail = fromC@example.com\n")
+	s.write(c, "a.gitconfig", "[include]\n\tpath = c.gitconfig\n")
+	s.write(c, "b.gitconfig", "[user]\n\temail = fromB@example.com\n")
+	main := s.write(c, "main.gitconfig", "[include]\n\tpath = a.gitconfig\n[include]\n\tpath = b.gitconfig\n")
+
+	cfg, err := ReadConfig(main, s.dir, "")
+	c.Assert(err, IsNil)
+	c.Assert(cfg.User.Email, Equals, "fromB@example.com")
+}
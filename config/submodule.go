@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+
+	format "gopkg.in/src-d/go-git.v4/plumbing/format/config"
+)
+
+var ErrSubmoduleConfigEmptyName = errors.New("submodule config: empty name")
+
+const (
+	submoduleSection = "submodule"
+	branchKey        = "branch"
+	activeKey        = "active"
+)
+
+// SubmoduleConfig contains the configuration for a given submodule, as read
+// from the "submodule.<name>" section of a git-config file. It holds the
+// settings "git submodule init" copies out of .gitmodules into the local
+// repository config, not the contents of .gitmodules itself.
+type SubmoduleConfig struct {
+	// Name of the submodule
+	Name string
+	// URL of the submodule repository, may have been rewritten locally
+	URL string
+	// Branch of the submodule repository to track when updating
+	Branch string
+	// Active tells whether this submodule is included in recursive
+	// operations, empty means the default from .gitmodules applies
+	Active string
+
+	raw *format.Subsection
+}
+
+// Validate validates the fields and returns an error if required ones are
+// missing
+func (s *SubmoduleConfig) Validate() error {
+	if s.Name == "" {
+		return ErrSubmoduleConfigEmptyName
+	}
+
+	return nil
+}
+
+func (c *Config) unmarshalSubmodules() {
+	s := c.merged.Section(submoduleSection)
+	for _, sub := range s.Subsections {
+		m := &SubmoduleConfig{}
+		m.unmarshal(sub)
+
+		c.Submodules[m.Name] = m
+	}
+}
+
+func (c *Config) marshalSubmodules() {
+	live := make(map[string]bool, len(c.Submodules))
+	for name, m := range c.Submodules {
+		live[name] = true
+
+		s := c.target(submoduleSection, name).Section(submoduleSection)
+		s.Subsections = replaceOrAppendSubsection(s.Subsections, m.marshal())
+	}
+
+	c.pruneSubsections(submoduleSection, live)
+}
+
+func (s *SubmoduleConfig) unmarshal(sub *format.Subsection) {
+	s.raw = sub
+
+	s.Name = sub.Name
+	s.URL = sub.Options.Get(urlKey)
+	s.Branch = sub.Options.Get(branchKey)
+	s.Active = sub.Options.Get(activeKey)
+}
+
+func (s *SubmoduleConfig) marshal() *format.Subsection {
+	if s.raw == nil {
+		s.raw = &format.Subsection{}
+	}
+
+	s.raw.Name = s.Name
+	s.raw.Options = replaceOption(s.raw.Options, urlKey, valueOrNone(s.URL))
+	s.raw.Options = replaceOption(s.raw.Options, branchKey, valueOrNone(s.Branch))
+	s.raw.Options = replaceOption(s.raw.Options, activeKey, valueOrNone(s.Active))
+
+	return s.raw
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type SubmoduleSuite struct{}
+
+var _ = Suite(&SubmoduleSuite{})
+
+func (s *SubmoduleSuite) TestUnmarshalMarshalRoundTrip(c *C) {
+	raw := []byte("[core]\n\tbare = false\n[submodule \"vendor/lib\"]\n\turl = https://example.com/lib.git\n\tbranch = main\n\tactive = true\n")
+
+	cfg := NewConfig()
+	c.Assert(cfg.Unmarshal(raw), IsNil)
+
+	m, ok := cfg.Submodules["vendor/lib"]
+	c.Assert(ok, Equals, true)
+	c.Assert(m.URL, Equals, "https://example.com/lib.git")
+	c.Assert(m.Branch, Equals, "main")
+	c.Assert(m.Active, Equals, "true")
+
+	out, err := cfg.Marshal()
+	c.Assert(err, IsNil)
+	c.Assert(string(out), Equals, string(raw))
+}
+
+func (s *SubmoduleSuite) TestValidate(c *C) {
+	m := &SubmoduleConfig{Name: "vendor/lib"}
+	c.Assert(m.Validate(), IsNil)
+
+	m = &SubmoduleConfig{}
+	c.Assert(m.Validate(), Equals, ErrSubmoduleConfigEmptyName)
+}
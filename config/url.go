@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"strings"
+
+	format "gopkg.in/src-d/go-git.v4/plumbing/format/config"
+)
+
+var ErrURLConfigEmptyName = errors.New("url config: empty name")
+
+const (
+	urlSection       = "url"
+	insteadOfKey     = "insteadOf"
+	pushInsteadOfKey = "pushInsteadOf"
+)
+
+// URLConfig contains a single URL rewrite rule, as read from the
+// "url.<base>" section of a git-config file: any URL prefixed by one of
+// InsteadOf or PushInsteadOf is rewritten to start with Name instead.
+type URLConfig struct {
+	// Name is the base URL being rewritten to, i.e. the subsection name
+	Name string
+	// InsteadOf is the set of prefixes rewritten to Name for both fetch and
+	// push
+	InsteadOf []string
+	// PushInsteadOf is the set of prefixes rewritten to Name for push only
+	PushInsteadOf []string
+
+	raw *format.Subsection
+}
+
+// Validate validates the fields and returns an error if required ones are
+// missing
+func (u *URLConfig) Validate() error {
+	if u.Name == "" {
+		return ErrURLConfigEmptyName
+	}
+
+	return nil
+}
+
+func (c *Config) unmarshalURLs() {
+	s := c.merged.Section(urlSection)
+	for _, sub := range s.Subsections {
+		u := &URLConfig{}
+		u.unmarshal(sub)
+
+		c.URLs[u.Name] = u
+	}
+}
+
+func (c *Config) marshalURLs() {
+	live := make(map[string]bool, len(c.URLs))
+	for name, u := range c.URLs {
+		live[name] = true
+
+		s := c.target(urlSection, name).Section(urlSection)
+		s.Subsections = replaceOrAppendSubsection(s.Subsections, u.marshal())
+	}
+
+	c.pruneSubsections(urlSection, live)
+}
+
+func (u *URLConfig) unmarshal(sub *format.Subsection) {
+	u.raw = sub
+
+	u.Name = sub.Name
+	u.InsteadOf = sub.Options.GetAll(insteadOfKey)
+	u.PushInsteadOf = sub.Options.GetAll(pushInsteadOfKey)
+}
+
+func (u *URLConfig) marshal() *format.Subsection {
+	if u.raw == nil {
+		u.raw = &format.Subsection{}
+	}
+
+	u.raw.Name = u.Name
+	u.raw.Options = replaceOption(u.raw.Options, insteadOfKey, u.InsteadOf)
+	u.raw.Options = replaceOption(u.raw.Options, pushInsteadOfKey, u.PushInsteadOf)
+
+	return u.raw
+}
+
+// RewriteURL applies the longest matching "url.<base>.insteadOf" prefix
+// rewrite to url, as used by fetch and clone. It returns url unchanged if
+// no rule matches.
+//
+// This package has no transport or remote layer of its own to call it
+// automatically: whatever code opens a fetch/clone connection in this
+// repository is responsible for passing the remote URL through RewriteURL
+// (and RewritePushURL, for push) before dialing it.
+func (c *Config) RewriteURL(url string) string {
+	return rewriteURL(c.URLs, url, false)
+}
+
+// RewritePushURL applies the longest matching "url.<base>.pushInsteadOf" or
+// "url.<base>.insteadOf" prefix rewrite to url, as used by push; a
+// pushInsteadOf match always takes priority over an insteadOf one. It
+// returns url unchanged if no rule matches. See the note on RewriteURL.
+func (c *Config) RewritePushURL(url string) string {
+	return rewriteURL(c.URLs, url, true)
+}
+
+func rewriteURL(urls map[string]*URLConfig, url string, push bool) string {
+	if push {
+		if rewritten, ok := rewriteURLWith(urls, url, func(u *URLConfig) []string {
+			return u.PushInsteadOf
+		}); ok {
+			return rewritten
+		}
+	}
+
+	if rewritten, ok := rewriteURLWith(urls, url, func(u *URLConfig) []string {
+		return u.InsteadOf
+	}); ok {
+		return rewritten
+	}
+
+	return url
+}
+
+// rewriteURLWith applies the longest prefix out of the list returned by
+// prefixesOf, across every entry in urls, reporting whether any rule
+// matched.
+func rewriteURLWith(urls map[string]*URLConfig, url string, prefixesOf func(*URLConfig) []string) (string, bool) {
+	var best *URLConfig
+	var bestPrefix string
+
+	for _, u := range urls {
+		for _, prefix := range prefixesOf(u) {
+			if !strings.HasPrefix(url, prefix) {
+				continue
+			}
+
+			if best == nil || len(prefix) > len(bestPrefix) {
+				best, bestPrefix = u, prefix
+			}
+		}
+	}
+
+	if best == nil {
+		return url, false
+	}
+
+	return best.Name + strings.TrimPrefix(url, bestPrefix), true
+}
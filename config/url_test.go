@@ -0,0 +1,47 @@
+package config
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type URLSuite struct{}
+
+var _ = Suite(&URLSuite{})
+
+func (s *URLSuite) TestUnmarshalMarshalRoundTrip(c *C) {
+	raw := []byte("[core]\n\tbare = false\n[url \"git@github.com:\"]\n\tinsteadOf = https://github.com/\n\tpushInsteadOf = https://github.com/\n")
+
+	cfg := NewConfig()
+	c.Assert(cfg.Unmarshal(raw), IsNil)
+
+	u, ok := cfg.URLs["git@github.com:"]
+	c.Assert(ok, Equals, true)
+	c.Assert(u.InsteadOf, DeepEquals, []string{"https://github.com/"})
+	c.Assert(u.PushInsteadOf, DeepEquals, []string{"https://github.com/"})
+
+	out, err := cfg.Marshal()
+	c.Assert(err, IsNil)
+	c.Assert(string(out), Equals, string(raw))
+}
+
+func (s *URLSuite) TestValidate(c *C) {
+	u := &URLConfig{Name: "git@github.com:"}
+	c.Assert(u.Validate(), IsNil)
+
+	u = &URLConfig{}
+	c.Assert(u.Validate(), Equals, ErrURLConfigEmptyName)
+}
+
+func (s *URLSuite) TestRewriteURL(c *C) {
+	cfg := NewConfig()
+	c.Assert(cfg.Unmarshal([]byte(
+		"[url \"git@github.com:\"]\n\tinsteadOf = https://github.com/\n\tpushInsteadOf = https://github.com/push/\n",
+	)), IsNil)
+
+	c.Assert(cfg.RewriteURL("https://github.com/foo/bar.git"), Equals, "git@github.com:foo/bar.git")
+	c.Assert(cfg.RewriteURL("https://example.com/foo/bar.git"), Equals, "https://example.com/foo/bar.git")
+
+	c.Assert(cfg.RewritePushURL("https://github.com/push/foo/bar.git"), Equals, "git@github.com:foo/bar.git")
+	// pushInsteadOf doesn't match here, but insteadOf still applies to push.
+	c.Assert(cfg.RewritePushURL("https://github.com/foo/bar.git"), Equals, "git@github.com:foo/bar.git")
+}